@@ -0,0 +1,144 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cli wires cosign's pkg/cosign library up to a cobra command tree.
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/pkg/cosign"
+)
+
+// GenerateKeyPair returns a cobra command that writes a new cosign key pair
+// (cosign.key/cosign.pub) to the current directory.
+func GenerateKeyPair() *cobra.Command {
+	o := &options.GenerateKeyPairOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "generate-key-pair",
+		Short: "Generate a new cosign key pair",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return generateKeyPairCmd(o)
+		},
+	}
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func generateKeyPairCmd(o *options.GenerateKeyPairOptions) error {
+	opts, err := keyGenOptsFromFlags(o)
+	if err != nil {
+		return err
+	}
+	if err := checkKeyFilesDontExist(); err != nil {
+		return err
+	}
+	keys, err := cosign.GenerateKeyPairWithOpts(opts, GetPass)
+	if err != nil {
+		return fmt.Errorf("generating key pair: %w", err)
+	}
+	return writeKeyFiles(keys)
+}
+
+// keyGenOptsFromFlags validates the --algorithm/--curve/--rsa-bits flags
+// against cosign's supported values, rather than letting an unrecognized
+// value (a typo, say) silently fall back to the ECDSA P-256 default.
+func keyGenOptsFromFlags(o *options.GenerateKeyPairOptions) (cosign.KeyGenOpts, error) {
+	algorithm := cosign.KeyAlgorithm(o.Algorithm)
+	switch algorithm {
+	case cosign.ECDSAKeyAlgorithm, cosign.RSAKeyAlgorithm, cosign.ED25519KeyAlgorithm:
+	default:
+		return cosign.KeyGenOpts{}, fmt.Errorf("unsupported --algorithm %q: must be ecdsa, rsa, or ed25519", o.Algorithm)
+	}
+
+	curve := cosign.ECDSACurve(o.Curve)
+	if algorithm == cosign.ECDSAKeyAlgorithm {
+		switch curve {
+		case cosign.P256Curve, cosign.P384Curve, cosign.P521Curve:
+		default:
+			return cosign.KeyGenOpts{}, fmt.Errorf("unsupported --curve %q: must be p256, p384, or p521", o.Curve)
+		}
+	}
+
+	if algorithm == cosign.RSAKeyAlgorithm {
+		switch o.RSABits {
+		case 2048, 3072, 4096:
+		default:
+			return cosign.KeyGenOpts{}, fmt.Errorf("unsupported --rsa-bits %d: must be 2048, 3072, or 4096", o.RSABits)
+		}
+	}
+
+	return cosign.KeyGenOpts{Algorithm: algorithm, Curve: curve, RSABits: o.RSABits}, nil
+}
+
+// checkKeyFilesDontExist fails fast if cosign.key/cosign.pub are already
+// present, so an accidental re-run doesn't burn an expensive keygen (RSA-4096
+// in particular) only to then refuse to write the result.
+func checkKeyFilesDontExist() error {
+	for _, name := range []string{"cosign.key", "cosign.pub"} {
+		if _, err := os.Stat(name); err == nil {
+			return fmt.Errorf("%s already exists, refusing to overwrite", name)
+		}
+	}
+	return nil
+}
+
+func writeKeyFiles(keys *cosign.Keys) error {
+	defer keys.Destroy()
+
+	if err := os.WriteFile("cosign.key", keys.PrivateBytes, 0600); err != nil {
+		return fmt.Errorf("writing private key: %w", err)
+	}
+	if err := os.WriteFile("cosign.pub", keys.PublicBytes, 0644); err != nil {
+		return fmt.Errorf("writing public key: %w", err)
+	}
+	fmt.Fprintln(os.Stderr, "Private key written to cosign.key")
+	fmt.Fprintln(os.Stderr, "Public key written to cosign.pub")
+	return nil
+}
+
+// GetPass prompts on stderr for the password protecting a cosign private
+// key, asking twice and requiring a match when confirm is true (generating
+// a new key), and once when reading an existing one.
+func GetPass(confirm bool) ([]byte, error) {
+	fmt.Fprint(os.Stderr, "Enter password for private key: ")
+	pw, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	if !confirm {
+		return pw, nil
+	}
+
+	fmt.Fprint(os.Stderr, "Enter password for private key again: ")
+	confirmPw, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(pw, confirmPw) {
+		return nil, errors.New("passwords do not match")
+	}
+	return pw, nil
+}
@@ -0,0 +1,41 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sigstore/cosign/pkg/cosign"
+)
+
+// GenerateKeyPairOptions are the command line flags for `cosign generate-key-pair`.
+type GenerateKeyPairOptions struct {
+	Algorithm string
+	Curve     string
+	RSABits   int
+}
+
+var _ Interface = (*GenerateKeyPairOptions)(nil)
+
+// AddFlags implements Interface.
+func (o *GenerateKeyPairOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Algorithm, "algorithm", "ecdsa",
+		"algorithm to use when generating the key pair: ecdsa, rsa, or ed25519")
+	cmd.Flags().StringVar(&o.Curve, "curve", "p256",
+		"ECDSA curve to use when --algorithm=ecdsa: p256, p384, or p521")
+	cmd.Flags().IntVar(&o.RSABits, "rsa-bits", cosign.DefaultRSABits,
+		"RSA key size in bits to use when --algorithm=rsa: 2048, 3072, or 4096")
+}
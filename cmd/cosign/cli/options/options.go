@@ -0,0 +1,27 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package options defines the flags for each cosign subcommand, kept
+// separate from cmd/cosign/cli so a command's flag set can be constructed
+// and inspected without pulling in the command's run logic.
+package options
+
+import "github.com/spf13/cobra"
+
+// Interface is implemented by every subcommand's options type, letting the
+// command constructor wire a flag set with a single AddFlags call.
+type Interface interface {
+	AddFlags(cmd *cobra.Command)
+}
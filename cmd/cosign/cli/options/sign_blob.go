@@ -0,0 +1,31 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import "github.com/spf13/cobra"
+
+// SignBlobOptions are the command line flags for `cosign sign-blob`.
+type SignBlobOptions struct {
+	KeyRef string
+}
+
+var _ Interface = (*SignBlobOptions)(nil)
+
+// AddFlags implements Interface.
+func (o *SignBlobOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.KeyRef, "key", "",
+		"path to the private key, or a pkcs11:/env:/file: key reference, to sign with")
+}
@@ -0,0 +1,81 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/pkg/cosign"
+
+	// Registers the "pkcs11" PrivateKeyProvider so --key pkcs11:... resolves.
+	_ "github.com/sigstore/cosign/pkg/cosign/pkcs11key"
+)
+
+// SignBlob returns a cobra command that signs the named blob file with the
+// key referenced by --key and prints the base64-encoded signature.
+func SignBlob() *cobra.Command {
+	o := &options.SignBlobOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "sign-blob <blob>",
+		Short: "Sign the contents of a blob",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return signBlobCmd(o, os.Stdout, args[0])
+		},
+	}
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func signBlobCmd(o *options.SignBlobOptions, out io.Writer, blobPath string) error {
+	if o.KeyRef == "" {
+		return fmt.Errorf("--key is required")
+	}
+
+	// The blob to sign is read from a named file rather than stdin, since
+	// GetPass also reads from stdin to prompt for a password-protected key
+	// and the two would otherwise collide on the same reader.
+	payload, err := os.Open(blobPath)
+	if err != nil {
+		return fmt.Errorf("opening blob: %w", err)
+	}
+	defer payload.Close()
+
+	// LoadPrivateKeyFromURI dispatches file/env/pkcs11 key references alike,
+	// so an HSM-resident key signs exactly like one on disk from here on.
+	sv, err := cosign.LoadPrivateKeyFromURI(o.KeyRef, GetPass)
+	if err != nil {
+		return fmt.Errorf("loading private key %q: %w", o.KeyRef, err)
+	}
+	if closer, ok := sv.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	sig, err := sv.SignMessage(payload)
+	if err != nil {
+		return fmt.Errorf("signing blob: %w", err)
+	}
+
+	_, err = fmt.Fprintln(out, base64.StdEncoding.EncodeToString(sig))
+	return err
+}
@@ -0,0 +1,124 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"filippo.io/age"
+	"github.com/pkg/errors"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+// ageIdentityEnvVar names the environment variable LoadPrivateKey consults
+// to decrypt an AgePrivateKeyPemType key: either an inline age identity
+// (as produced by age-keygen), or a path to an identity file. Only native
+// X25519 identities are accepted, inline or in the file; plugin identities
+// (such as those of a YubiKey age plugin) are not parsed.
+const ageIdentityEnvVar = "COSIGN_AGE_IDENTITY"
+
+// MarshallKeyPairWithAge wraps keypair's PKCS#8 private key to one or more
+// age recipients instead of cosign's usual scrypt+secretbox password
+// scheme. This lets a signing key be shared by a team, or bound to a
+// hardware token through a YubiKey age plugin, without anyone needing to
+// remember a passphrase.
+func MarshallKeyPairWithAge(keypair Key, recipients ...age.Recipient) (*Keys, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("at least one age recipient is required")
+	}
+
+	x509Encoded, err := x509.MarshalPKCS8PrivateKey(keypair.private)
+	if err != nil {
+		return nil, errors.Wrap(err, "x509 encoding private key")
+	}
+	secret := NewSecretBytes(x509Encoded)
+	defer secret.Destroy()
+
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipients...)
+	if err != nil {
+		return nil, errors.Wrap(err, "age encrypt")
+	}
+	if _, err := w.Write(secret.Bytes()); err != nil {
+		return nil, errors.Wrap(err, "age encrypt")
+	}
+	if err := w.Close(); err != nil {
+		return nil, errors.Wrap(err, "age encrypt")
+	}
+
+	privBytes := pem.EncodeToMemory(&pem.Block{
+		Bytes: buf.Bytes(),
+		Type:  AgePrivateKeyPemType,
+	})
+
+	pubBytes, err := cryptoutils.MarshalPublicKeyToPEM(keypair.public)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Keys{
+		PrivateBytes: privBytes,
+		PublicBytes:  pubBytes,
+	}, nil
+}
+
+// loadAgeEncryptedPrivateKey unwraps an AgePrivateKeyPemType block using the
+// identity named by COSIGN_AGE_IDENTITY.
+func loadAgeEncryptedPrivateKey(ciphertext []byte) (signature.SignerVerifier, error) {
+	identities, err := ageIdentitiesFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := age.Decrypt(bytes.NewReader(ciphertext), identities...)
+	if err != nil {
+		return nil, errors.Wrap(err, "age decrypt")
+	}
+	x509Encoded, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "age decrypt")
+	}
+	secret := NewSecretBytes(x509Encoded)
+	defer secret.Destroy()
+
+	return signerVerifierFromPKCS8DER(secret.Bytes())
+}
+
+func ageIdentitiesFromEnv() ([]age.Identity, error) {
+	ref := os.Getenv(ageIdentityEnvVar)
+	if ref == "" {
+		return nil, fmt.Errorf("%s must be set to an age identity (or identity file path) to decrypt this key", ageIdentityEnvVar)
+	}
+
+	if identity, err := age.ParseX25519Identity(ref); err == nil {
+		return []age.Identity{identity}, nil
+	}
+
+	f, err := os.Open(ref)
+	if err != nil {
+		return nil, fmt.Errorf("%s is neither a valid age identity nor a readable identity file: %w", ageIdentityEnvVar, err)
+	}
+	defer f.Close()
+
+	return age.ParseIdentities(f)
+}
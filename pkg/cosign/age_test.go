@@ -0,0 +1,98 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"bytes"
+	"testing"
+
+	"filippo.io/age"
+)
+
+func TestMarshallKeyPairWithAgeRoundTrip(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("age.GenerateX25519Identity: %v", err)
+	}
+	t.Setenv(ageIdentityEnvVar, identity.String())
+
+	priv, err := GenerateED25519PrivateKey()
+	if err != nil {
+		t.Fatalf("GenerateED25519PrivateKey: %v", err)
+	}
+
+	keys, err := MarshallKeyPairWithAge(Key{priv, priv.Public()}, identity.Recipient())
+	if err != nil {
+		t.Fatalf("MarshallKeyPairWithAge: %v", err)
+	}
+
+	sv, err := LoadPrivateKey(keys.PrivateBytes, nil)
+	if err != nil {
+		t.Fatalf("LoadPrivateKey: %v", err)
+	}
+
+	msg := []byte("payload")
+	sig, err := sv.SignMessage(bytes.NewReader(msg))
+	if err != nil {
+		t.Fatalf("SignMessage: %v", err)
+	}
+	if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader(msg)); err != nil {
+		t.Fatalf("VerifySignature: %v", err)
+	}
+}
+
+func TestMarshallKeyPairWithAgeRequiresRecipient(t *testing.T) {
+	priv, err := GenerateED25519PrivateKey()
+	if err != nil {
+		t.Fatalf("GenerateED25519PrivateKey: %v", err)
+	}
+
+	if _, err := MarshallKeyPairWithAge(Key{priv, priv.Public()}); err == nil {
+		t.Fatal("expected an error with no recipients")
+	}
+}
+
+func TestLoadAgeEncryptedPrivateKeyWrongIdentity(t *testing.T) {
+	encryptTo, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("age.GenerateX25519Identity: %v", err)
+	}
+	wrongIdentity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("age.GenerateX25519Identity: %v", err)
+	}
+
+	priv, err := GenerateED25519PrivateKey()
+	if err != nil {
+		t.Fatalf("GenerateED25519PrivateKey: %v", err)
+	}
+	keys, err := MarshallKeyPairWithAge(Key{priv, priv.Public()}, encryptTo.Recipient())
+	if err != nil {
+		t.Fatalf("MarshallKeyPairWithAge: %v", err)
+	}
+
+	t.Setenv(ageIdentityEnvVar, wrongIdentity.String())
+	if _, err := LoadPrivateKey(keys.PrivateBytes, nil); err == nil {
+		t.Fatal("expected an error decrypting with an identity that isn't a recipient")
+	}
+}
+
+func TestLoadAgeEncryptedPrivateKeyMissingIdentity(t *testing.T) {
+	t.Setenv(ageIdentityEnvVar, "")
+	if _, err := loadAgeEncryptedPrivateKey([]byte("irrelevant")); err == nil {
+		t.Fatal("expected an error when COSIGN_AGE_IDENTITY is unset")
+	}
+}
@@ -0,0 +1,101 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestImportKeyPairPKCS8Ed25519(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey: %v", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey: %v", err)
+	}
+	path := writePEM(t, PKCS8PrivateKeyPemType, der)
+
+	keys, err := ImportKeyPair(path, staticPass("s3cret"))
+	if err != nil {
+		t.Fatalf("ImportKeyPair: %v", err)
+	}
+	defer keys.Destroy()
+
+	sv, err := LoadPrivateKey(keys.PrivateBytes, []byte("s3cret"))
+	if err != nil {
+		t.Fatalf("LoadPrivateKey: %v", err)
+	}
+	gotPub, err := sv.PublicKey()
+	if err != nil {
+		t.Fatalf("PublicKey: %v", err)
+	}
+	if !pub.Equal(gotPub) {
+		t.Fatal("imported public key does not match the original")
+	}
+}
+
+func TestImportKeyPairOpenSSH(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey: %v", err)
+	}
+
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatalf("ssh.MarshalPrivateKey: %v", err)
+	}
+	path := writePEM(t, block.Type, block.Bytes)
+
+	keys, err := ImportKeyPair(path, staticPass("s3cret"))
+	if err != nil {
+		t.Fatalf("ImportKeyPair: %v", err)
+	}
+	defer keys.Destroy()
+
+	if _, err := LoadPrivateKey(keys.PrivateBytes, []byte("s3cret")); err != nil {
+		t.Fatalf("LoadPrivateKey: %v", err)
+	}
+}
+
+func TestImportKeyPairUnsupportedPemType(t *testing.T) {
+	path := writePEM(t, "SOMETHING ELSE", []byte("not a key"))
+
+	if _, err := ImportKeyPair(path, staticPass("s3cret")); err == nil {
+		t.Fatal("expected an error for an unsupported pem type")
+	}
+}
+
+func writePEM(t *testing.T, pemType string, der []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: pemType, Bytes: der})
+	if err := os.WriteFile(path, pemBytes, 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
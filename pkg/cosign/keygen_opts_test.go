@@ -0,0 +1,69 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"testing"
+)
+
+func TestGenerateKeyPairWithOptsPerAlgorithm(t *testing.T) {
+	cases := []struct {
+		name string
+		opts KeyGenOpts
+	}{
+		{"ecdsa p256", KeyGenOpts{Algorithm: ECDSAKeyAlgorithm, Curve: P256Curve}},
+		{"ecdsa p384", KeyGenOpts{Algorithm: ECDSAKeyAlgorithm, Curve: P384Curve}},
+		{"rsa 2048", KeyGenOpts{Algorithm: RSAKeyAlgorithm, RSABits: 2048}},
+		{"ed25519", KeyGenOpts{Algorithm: ED25519KeyAlgorithm}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			keys, err := GenerateKeyPairWithOpts(c.opts, staticPass("s3cret"))
+			if err != nil {
+				t.Fatalf("GenerateKeyPairWithOpts: %v", err)
+			}
+			defer keys.Destroy()
+
+			sv, err := LoadPrivateKey(keys.PrivateBytes, []byte("s3cret"))
+			if err != nil {
+				t.Fatalf("LoadPrivateKey: %v", err)
+			}
+
+			pub, err := sv.PublicKey()
+			if err != nil {
+				t.Fatalf("PublicKey: %v", err)
+			}
+			switch c.opts.Algorithm {
+			case ECDSAKeyAlgorithm:
+				if _, ok := pub.(*ecdsa.PublicKey); !ok {
+					t.Fatalf("got public key type %T, want *ecdsa.PublicKey", pub)
+				}
+			case RSAKeyAlgorithm:
+				if _, ok := pub.(*rsa.PublicKey); !ok {
+					t.Fatalf("got public key type %T, want *rsa.PublicKey", pub)
+				}
+			case ED25519KeyAlgorithm:
+				if _, ok := pub.(ed25519.PublicKey); !ok {
+					t.Fatalf("got public key type %T, want ed25519.PublicKey", pub)
+				}
+			}
+		})
+	}
+}
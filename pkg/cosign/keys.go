@@ -18,10 +18,12 @@ package cosign
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	_ "crypto/sha256" // for `crypto.SHA256`
+	_ "crypto/sha512" // for `crypto.SHA384`/`crypto.SHA512`
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
@@ -30,6 +32,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/theupdateframework/go-tuf/encrypted"
+	"golang.org/x/crypto/ssh"
 
 	"github.com/sigstore/cosign/pkg/oci/static"
 	"github.com/sigstore/sigstore/pkg/cryptoutils"
@@ -37,12 +40,98 @@ import (
 )
 
 const (
-	PrivateKeyPemType    = "ENCRYPTED COSIGN PRIVATE KEY"
-	RSAPrivateKeyPemType = "RSA PRIVATE KEY"
-	ECPrivateKeyPemType  = "EC PRIVATE KEY"
-	BundleKey            = static.BundleAnnotationKey
+	PrivateKeyPemType        = "ENCRYPTED COSIGN PRIVATE KEY"
+	RSAPrivateKeyPemType     = "RSA PRIVATE KEY"
+	ECPrivateKeyPemType      = "EC PRIVATE KEY"
+	PKCS8PrivateKeyPemType   = "PRIVATE KEY"
+	OpenSSHPrivateKeyPemType = "OPENSSH PRIVATE KEY"
+	AgePrivateKeyPemType     = "AGE ENCRYPTED COSIGN PRIVATE KEY"
+	PGPPrivateKeyPemType     = "PGP ENCRYPTED COSIGN PRIVATE KEY"
+	BundleKey                = static.BundleAnnotationKey
 )
 
+// KeyAlgorithm identifies the asymmetric algorithm to use when generating a
+// new cosign key pair.
+type KeyAlgorithm string
+
+const (
+	ECDSAKeyAlgorithm   KeyAlgorithm = "ecdsa"
+	RSAKeyAlgorithm     KeyAlgorithm = "rsa"
+	ED25519KeyAlgorithm KeyAlgorithm = "ed25519"
+)
+
+// ECDSACurve identifies the elliptic curve to use when generating an ECDSA
+// key pair.
+type ECDSACurve string
+
+const (
+	P256Curve ECDSACurve = "p256"
+	P384Curve ECDSACurve = "p384"
+	P521Curve ECDSACurve = "p521"
+)
+
+const (
+	DefaultRSABits = 2048
+)
+
+// KeyGenOpts controls the algorithm and strength used when generating a
+// new cosign key pair. The digest paired with the key for signing is not
+// configured here: LoadECDSAPrivateKey/LoadRSAPrivateKey derive it from the
+// curve/key size actually generated (see HashForECDSACurve/hashForRSABits),
+// so it always tracks the key's real strength.
+type KeyGenOpts struct {
+	Algorithm KeyAlgorithm
+	Curve     ECDSACurve // only used when Algorithm is ECDSAKeyAlgorithm
+	RSABits   int        // only used when Algorithm is RSAKeyAlgorithm
+}
+
+// DefaultKeyGenOpts matches the historical behavior of GeneratePrivateKey:
+// an ECDSA P-256 key, signed with SHA-256.
+var DefaultKeyGenOpts = KeyGenOpts{
+	Algorithm: ECDSAKeyAlgorithm,
+	Curve:     P256Curve,
+}
+
+func ellipticCurve(curve ECDSACurve) elliptic.Curve {
+	switch curve {
+	case P384Curve:
+		return elliptic.P384()
+	case P521Curve:
+		return elliptic.P521()
+	default:
+		return elliptic.P256()
+	}
+}
+
+// HashForECDSACurve returns the digest cosign pairs with a given ECDSA
+// curve: SHA-256 for P-256, and the stronger SHA-384/SHA-512 for P-384/P-521
+// so the hash strength tracks the curve's security level. It's exported so
+// PrivateKeyProvider implementations (e.g. pkcs11key) that construct their
+// own *ecdsa.PublicKey can pick the same digest cosign would.
+func HashForECDSACurve(curve elliptic.Curve) crypto.Hash {
+	switch curve.Params().Name {
+	case elliptic.P384().Params().Name:
+		return crypto.SHA384
+	case elliptic.P521().Params().Name:
+		return crypto.SHA512
+	default:
+		return crypto.SHA256
+	}
+}
+
+// hashForRSABits returns the digest cosign pairs with a given RSA key size,
+// stepping up to SHA-384/SHA-512 for 3072/4096-bit keys.
+func hashForRSABits(bits int) crypto.Hash {
+	switch {
+	case bits >= 4096:
+		return crypto.SHA512
+	case bits >= 3072:
+		return crypto.SHA384
+	default:
+		return crypto.SHA256
+	}
+}
+
 type PassFunc func(bool) ([]byte, error)
 
 type Key struct {
@@ -53,13 +142,34 @@ type Key struct {
 type Keys struct {
 	PrivateBytes []byte
 	PublicBytes  []byte
-	password     []byte
+	password     *SecretBytes
+}
+
+// Destroy wipes the password (and any other sensitive material Keys holds)
+// from memory. Callers that are done with a Keys value should call Destroy
+// once they've written out PrivateBytes/PublicBytes.
+func (k *Keys) Destroy() {
+	k.password.Destroy()
 }
 
 func GeneratePrivateKey() (*ecdsa.PrivateKey, error) {
 	return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 }
 
+// GenerateED25519PrivateKey generates a new Ed25519 private key.
+func GenerateED25519PrivateKey() (ed25519.PrivateKey, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return priv, nil
+}
+
+// GenerateRSAPrivateKey generates a new RSA private key of the given size.
+func GenerateRSAPrivateKey(bits int) (*rsa.PrivateKey, error) {
+	return rsa.GenerateKey(rand.Reader, bits)
+}
+
 func ImportKeyPair(keyPath string, pf PassFunc) (*Keys, error) {
 
 	kb, err := os.ReadFile(filepath.Clean(keyPath))
@@ -71,25 +181,76 @@ func ImportKeyPair(keyPath string, pf PassFunc) (*Keys, error) {
 	if p == nil {
 		return nil, errors.New("invalid pem block")
 	}
-	if p.Type != RSAPrivateKeyPemType && p.Type != ECPrivateKeyPemType {
-		return nil, fmt.Errorf("unsupported pem type: %s", p.Type)
-	}
 
-	if p.Type == RSAPrivateKeyPemType {
+	switch p.Type {
+	case RSAPrivateKeyPemType:
 		pk, err := x509.ParsePKCS1PrivateKey(p.Bytes)
 		if err != nil {
 			return nil, fmt.Errorf("parsing error")
 		}
 		return MarshallKeyPair(Key{pk, pk.Public()}, pf)
-	} else {
-
+	case ECPrivateKeyPemType:
 		pk, err := x509.ParseECPrivateKey(p.Bytes)
 		if err != nil {
 			return nil, fmt.Errorf("parsing error")
 		}
 		return MarshallKeyPair(Key{pk, pk.Public()}, pf)
+	case PKCS8PrivateKeyPemType:
+		return importPKCS8KeyPair(p.Bytes, pf)
+	case OpenSSHPrivateKeyPemType:
+		return importOpenSSHKeyPair(kb, pf)
+	default:
+		return nil, fmt.Errorf("unsupported pem type: %s", p.Type)
 	}
+}
 
+// importPKCS8KeyPair parses a PKCS#8 encoded private key of any of the
+// algorithms cosign supports (RSA, ECDSA, Ed25519) and re-encrypts it under
+// cosign's own password-based scheme.
+func importPKCS8KeyPair(der []byte, pf PassFunc) (*Keys, error) {
+	pk, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("parsing error")
+	}
+
+	signer, ok := pk.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported pkcs8 key type: %T", pk)
+	}
+	return MarshallKeyPair(Key{pk, signer.Public()}, pf)
+}
+
+// importOpenSSHKeyPair parses an OpenSSH-format private key, decrypting it
+// with a passphrase obtained through pf if necessary, and re-encrypts it
+// under cosign's own password-based scheme.
+func importOpenSSHKeyPair(pemBytes []byte, pf PassFunc) (*Keys, error) {
+	pk, err := ssh.ParseRawPrivateKey(pemBytes)
+	if err != nil {
+		if _, ok := err.(*ssh.PassphraseMissingError); !ok {
+			return nil, fmt.Errorf("parsing error")
+		}
+		passphrase, passErr := pf(false)
+		if passErr != nil {
+			return nil, passErr
+		}
+		pk, err = ssh.ParseRawPrivateKeyWithPassphrase(pemBytes, passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("parsing error")
+		}
+	}
+
+	// x/crypto/ssh hands back Ed25519 keys as *ed25519.PrivateKey, unlike
+	// RSA/ECDSA which already come back as *rsa.PrivateKey/*ecdsa.PrivateKey;
+	// x509.MarshalPKCS8PrivateKey only recognizes the dereferenced value.
+	if epk, ok := pk.(*ed25519.PrivateKey); ok {
+		pk = *epk
+	}
+
+	signer, ok := pk.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported openssh key type: %T", pk)
+	}
+	return MarshallKeyPair(Key{pk, signer.Public()}, pf)
 }
 
 func MarshallKeyPair(keypair Key, pf PassFunc) (*Keys, error) {
@@ -98,14 +259,18 @@ func MarshallKeyPair(keypair Key, pf PassFunc) (*Keys, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "x509 encoding private key")
 	}
+	secretKey := NewSecretBytes(x509Encoded)
+	defer secretKey.Destroy()
 
-	password, err := pf(true)
+	rawPassword, err := pf(true)
 	if err != nil {
 		return nil, err
 	}
+	password := NewSecretBytes(rawPassword)
 
-	encBytes, err := encrypted.Encrypt(x509Encoded, password)
+	encBytes, err := encrypted.Encrypt(secretKey.Bytes(), password.Bytes())
 	if err != nil {
+		password.Destroy()
 		return nil, err
 	}
 
@@ -118,6 +283,7 @@ func MarshallKeyPair(keypair Key, pf PassFunc) (*Keys, error) {
 	// Now do the public key
 	pubBytes, err := cryptoutils.MarshalPublicKeyToPEM(keypair.public)
 	if err != nil {
+		password.Destroy()
 		return nil, err
 	}
 
@@ -133,41 +299,59 @@ func GenerateKeyPair(pf PassFunc) (*Keys, error) {
 	if err != nil {
 		return nil, err
 	}
+	zeroizeECDSAOnFinalize(priv)
 
-	x509Encoded, err := x509.MarshalPKCS8PrivateKey(priv)
-	if err != nil {
-		return nil, errors.Wrap(err, "x509 encoding private key")
-	}
-	// Encrypt the private key and store it.
-	password, err := pf(true)
-	if err != nil {
-		return nil, err
-	}
-	encBytes, err := encrypted.Encrypt(x509Encoded, password)
+	return MarshallKeyPair(Key{priv, priv.Public()}, pf)
+}
+
+// GenerateKeyPairEd25519 is the Ed25519 equivalent of GenerateKeyPair.
+func GenerateKeyPairEd25519(pf PassFunc) (*Keys, error) {
+	priv, err := GenerateED25519PrivateKey()
 	if err != nil {
 		return nil, err
 	}
-	// store in PEM format
-	privBytes := pem.EncodeToMemory(&pem.Block{
-		Bytes: encBytes,
-		Type:  PrivateKeyPemType,
-	})
 
-	// Now do the public key
-	pubBytes, err := cryptoutils.MarshalPublicKeyToPEM(&priv.PublicKey)
-	if err != nil {
-		return nil, err
+	return MarshallKeyPair(Key{priv, priv.Public()}, pf)
+}
+
+// GenerateKeyPairWithOpts generates a new cosign key pair using the
+// algorithm and curve/size described by opts. The signing digest is not
+// taken from opts: it follows from the curve/size of the generated key.
+func GenerateKeyPairWithOpts(opts KeyGenOpts, pf PassFunc) (*Keys, error) {
+	switch opts.Algorithm {
+	case ED25519KeyAlgorithm:
+		return GenerateKeyPairEd25519(pf)
+	case RSAKeyAlgorithm:
+		bits := opts.RSABits
+		if bits == 0 {
+			bits = DefaultRSABits
+		}
+		priv, err := GenerateRSAPrivateKey(bits)
+		if err != nil {
+			return nil, err
+		}
+		zeroizeRSAOnFinalize(priv)
+		return MarshallKeyPair(Key{priv, priv.Public()}, pf)
+	default:
+		priv, err := ecdsa.GenerateKey(ellipticCurve(opts.Curve), rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		zeroizeECDSAOnFinalize(priv)
+		return MarshallKeyPair(Key{priv, priv.Public()}, pf)
 	}
+}
 
-	return &Keys{
-		PrivateBytes: privBytes,
-		PublicBytes:  pubBytes,
-		password:     password,
-	}, nil
+// GenerateKeyPairWithAlgorithm generates a new cosign key pair using the
+// requested algorithm and cosign's default curve/size for that algorithm.
+func GenerateKeyPairWithAlgorithm(algorithm KeyAlgorithm, pf PassFunc) (*Keys, error) {
+	opts := DefaultKeyGenOpts
+	opts.Algorithm = algorithm
+	return GenerateKeyPairWithOpts(opts, pf)
 }
 
 func (k *Keys) Password() []byte {
-	return k.password
+	return k.password.Bytes()
 }
 
 func PemToECDSAKey(pemBytes []byte) (*ecdsa.PublicKey, error) {
@@ -189,7 +373,15 @@ func LoadPrivateKey(key []byte, pass []byte) (signature.SignerVerifier, error) {
 	if p == nil {
 		return nil, errors.New("invalid pem block")
 	}
-	if p.Type != PrivateKeyPemType {
+
+	switch p.Type {
+	case AgePrivateKeyPemType:
+		return loadAgeEncryptedPrivateKey(p.Bytes)
+	case PGPPrivateKeyPemType:
+		return loadPGPEncryptedPrivateKey(p.Bytes)
+	case PrivateKeyPemType:
+		// handled below
+	default:
 		return nil, fmt.Errorf("unsupported pem type: %s", p.Type)
 	}
 
@@ -197,18 +389,33 @@ func LoadPrivateKey(key []byte, pass []byte) (signature.SignerVerifier, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "decrypt")
 	}
+	secret := NewSecretBytes(x509Encoded)
+	defer secret.Destroy()
+
+	return signerVerifierFromPKCS8DER(secret.Bytes())
+}
 
-	pk, err := x509.ParsePKCS8PrivateKey(x509Encoded)
+// signerVerifierFromPKCS8DER parses an unencrypted PKCS#8 DER blob and
+// builds the signature.SignerVerifier appropriate to the key type it holds.
+// It's the common tail shared by every key-wrapping scheme (password,
+// age, PGP) once they've each produced the same plaintext DER bytes.
+func signerVerifierFromPKCS8DER(der []byte) (signature.SignerVerifier, error) {
+	pk, err := x509.ParsePKCS8PrivateKey(der)
 	if err != nil {
 		return nil, errors.Wrap(err, "parsing private key")
 	}
-	switch pk.(type) {
+	switch key := pk.(type) {
 	case *rsa.PrivateKey:
-		return LoadRSAPrivateKey(key, pass)
+		zeroizeRSAOnFinalize(key)
+		return signature.LoadRSAPKCS1v15SignerVerifier(key, hashForRSABits(key.N.BitLen()))
 	case *ecdsa.PrivateKey:
-		return LoadECDSAPrivateKey(key, pass)
+		zeroizeECDSAOnFinalize(key)
+		return signature.LoadECDSASignerVerifier(key, HashForECDSACurve(key.Curve))
+	case ed25519.PrivateKey:
+		return signature.LoadED25519SignerVerifier(key)
+	default:
+		return nil, fmt.Errorf("unsupported private key type: %T", pk)
 	}
-	return nil, errors.Wrap(err, "loading private key")
 }
 
 func LoadECDSAPrivateKey(key []byte, pass []byte) (*signature.ECDSASignerVerifier, error) {
@@ -225,8 +432,10 @@ func LoadECDSAPrivateKey(key []byte, pass []byte) (*signature.ECDSASignerVerifie
 	if err != nil {
 		return nil, errors.Wrap(err, "decrypt")
 	}
+	secret := NewSecretBytes(x509Encoded)
+	defer secret.Destroy()
 
-	pk, err := x509.ParsePKCS8PrivateKey(x509Encoded)
+	pk, err := x509.ParsePKCS8PrivateKey(secret.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "parsing private key")
 	}
@@ -234,10 +443,11 @@ func LoadECDSAPrivateKey(key []byte, pass []byte) (*signature.ECDSASignerVerifie
 	if !ok {
 		return nil, errors.New("invalid private key")
 	}
-	return signature.LoadECDSASignerVerifier(epk, crypto.SHA256)
+	zeroizeECDSAOnFinalize(epk)
+	return signature.LoadECDSASignerVerifier(epk, HashForECDSACurve(epk.Curve))
 }
 
-func LoadRSAPrivateKey(key []byte, pass []byte) (*signature.RSAPKCS1v15SignerVerifier, error) {
+func LoadED25519PrivateKey(key []byte, pass []byte) (*signature.ED25519SignerVerifier, error) {
 	// Decrypt first
 	p, _ := pem.Decode(key)
 	if p == nil {
@@ -251,12 +461,46 @@ func LoadRSAPrivateKey(key []byte, pass []byte) (*signature.RSAPKCS1v15SignerVer
 	if err != nil {
 		return nil, errors.Wrap(err, "decrypt")
 	}
+	secret := NewSecretBytes(x509Encoded)
+	defer secret.Destroy()
 
-	pk, err := x509.ParsePKCS8PrivateKey(x509Encoded)
+	pk, err := x509.ParsePKCS8PrivateKey(secret.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing private key")
+	}
+	epk, ok := pk.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("invalid private key")
+	}
+	return signature.LoadED25519SignerVerifier(epk)
+}
 
+func LoadRSAPrivateKey(key []byte, pass []byte) (*signature.RSAPKCS1v15SignerVerifier, error) {
+	// Decrypt first
+	p, _ := pem.Decode(key)
+	if p == nil {
+		return nil, errors.New("invalid pem block")
+	}
+	if p.Type != PrivateKeyPemType {
+		return nil, fmt.Errorf("unsupported pem type: %s", p.Type)
+	}
+
+	x509Encoded, err := encrypted.Decrypt(p.Bytes, pass)
+	if err != nil {
+		return nil, errors.Wrap(err, "decrypt")
+	}
+	secret := NewSecretBytes(x509Encoded)
+	defer secret.Destroy()
+
+	pk, err := x509.ParsePKCS8PrivateKey(secret.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "parsing private key")
 	}
 
-	return signature.LoadRSAPKCS1v15SignerVerifier(pk.(*rsa.PrivateKey), crypto.SHA256)
+	rpk, ok := pk.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("invalid private key")
+	}
+	zeroizeRSAOnFinalize(rpk)
+	return signature.LoadRSAPKCS1v15SignerVerifier(rpk, hashForRSABits(rpk.N.BitLen()))
 }
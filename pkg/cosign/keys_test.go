@@ -0,0 +1,84 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"bytes"
+	"testing"
+)
+
+func staticPass(pw string) PassFunc {
+	return func(_ bool) ([]byte, error) {
+		return []byte(pw), nil
+	}
+}
+
+func TestGenerateED25519PrivateKey(t *testing.T) {
+	priv, err := GenerateED25519PrivateKey()
+	if err != nil {
+		t.Fatalf("GenerateED25519PrivateKey: %v", err)
+	}
+	if len(priv) == 0 {
+		t.Fatal("expected a non-empty private key")
+	}
+}
+
+func TestGenerateKeyPairEd25519RoundTrip(t *testing.T) {
+	keys, err := GenerateKeyPairEd25519(staticPass("s3cret"))
+	if err != nil {
+		t.Fatalf("GenerateKeyPairEd25519: %v", err)
+	}
+	defer keys.Destroy()
+
+	sv, err := LoadPrivateKey(keys.PrivateBytes, []byte("s3cret"))
+	if err != nil {
+		t.Fatalf("LoadPrivateKey: %v", err)
+	}
+
+	msg := bytes.NewReader([]byte("payload"))
+	sig, err := sv.SignMessage(msg)
+	if err != nil {
+		t.Fatalf("SignMessage: %v", err)
+	}
+
+	if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader([]byte("payload"))); err != nil {
+		t.Fatalf("VerifySignature: %v", err)
+	}
+}
+
+func TestLoadRSAPrivateKeyRejectsWrongKeyType(t *testing.T) {
+	keys, err := GenerateKeyPairEd25519(staticPass("s3cret"))
+	if err != nil {
+		t.Fatalf("GenerateKeyPairEd25519: %v", err)
+	}
+	defer keys.Destroy()
+
+	if _, err := LoadRSAPrivateKey(keys.PrivateBytes, []byte("s3cret")); err == nil {
+		t.Fatal("expected an error loading an Ed25519 key as an RSA key, not a panic")
+	}
+}
+
+func TestGenerateKeyPairEd25519WrongPassword(t *testing.T) {
+	keys, err := GenerateKeyPairEd25519(staticPass("s3cret"))
+	if err != nil {
+		t.Fatalf("GenerateKeyPairEd25519: %v", err)
+	}
+	defer keys.Destroy()
+
+	if _, err := LoadPrivateKey(keys.PrivateBytes, []byte("wrong")); err == nil {
+		t.Fatal("expected an error decrypting with the wrong password")
+	}
+}
@@ -0,0 +1,95 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os/exec"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/pkg/errors"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+// MarshallKeyPairWithPGP wraps keypair's PKCS#8 private key to one or more
+// PGP public keys instead of cosign's usual scrypt+secretbox password
+// scheme, so the key can only be unwrapped by someone holding the matching
+// PGP private key (commonly smartcard- or YubiKey-resident).
+func MarshallKeyPairWithPGP(keypair Key, recipients []*openpgp.Entity) (*Keys, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("at least one PGP recipient is required")
+	}
+
+	x509Encoded, err := x509.MarshalPKCS8PrivateKey(keypair.private)
+	if err != nil {
+		return nil, errors.Wrap(err, "x509 encoding private key")
+	}
+	secret := NewSecretBytes(x509Encoded)
+	defer secret.Destroy()
+
+	var buf bytes.Buffer
+	w, err := openpgp.Encrypt(&buf, recipients, nil, nil, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "pgp encrypt")
+	}
+	if _, err := w.Write(secret.Bytes()); err != nil {
+		return nil, errors.Wrap(err, "pgp encrypt")
+	}
+	if err := w.Close(); err != nil {
+		return nil, errors.Wrap(err, "pgp encrypt")
+	}
+
+	privBytes := pem.EncodeToMemory(&pem.Block{
+		Bytes: buf.Bytes(),
+		Type:  PGPPrivateKeyPemType,
+	})
+
+	pubBytes, err := cryptoutils.MarshalPublicKeyToPEM(keypair.public)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Keys{
+		PrivateBytes: privBytes,
+		PublicBytes:  pubBytes,
+	}, nil
+}
+
+// loadPGPEncryptedPrivateKey unwraps a PGPPrivateKeyPemType block by
+// shelling out to `gpg --decrypt`, so the running gpg-agent (and whatever
+// it has cached, or whatever smartcard it can prompt for) handles the
+// private key operation - cosign never needs its own PGP passphrase UI.
+func loadPGPEncryptedPrivateKey(ciphertext []byte) (signature.SignerVerifier, error) {
+	cmd := exec.Command("gpg", "--decrypt", "--quiet", "--batch")
+	cmd.Stdin = bytes.NewReader(ciphertext)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("gpg --decrypt failed (is gpg-agent running?): %w: %s", err, stderr.String())
+	}
+
+	secret := NewSecretBytes(stdout.Bytes())
+	defer secret.Destroy()
+
+	return signerVerifierFromPKCS8DER(secret.Bytes())
+}
@@ -0,0 +1,88 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// TestMarshallKeyPairWithPGPRoundTrip exercises the encrypt side of the PGP
+// key-wrapping path directly against the openpgp library. It deliberately
+// doesn't go through loadPGPEncryptedPrivateKey, which shells out to the
+// system `gpg`/gpg-agent: that's an external, stateful dependency a
+// hermetic unit test shouldn't assume is configured with the right secret
+// key, so it's left to integration/manual testing.
+func TestMarshallKeyPairWithPGPRoundTrip(t *testing.T) {
+	entity, err := openpgp.NewEntity("cosign test", "", "cosign-test@example.com", nil)
+	if err != nil {
+		t.Fatalf("openpgp.NewEntity: %v", err)
+	}
+
+	priv, err := GenerateED25519PrivateKey()
+	if err != nil {
+		t.Fatalf("GenerateED25519PrivateKey: %v", err)
+	}
+
+	keys, err := MarshallKeyPairWithPGP(Key{priv, priv.Public()}, []*openpgp.Entity{entity})
+	if err != nil {
+		t.Fatalf("MarshallKeyPairWithPGP: %v", err)
+	}
+
+	p, _ := pem.Decode(keys.PrivateBytes)
+	if p == nil || p.Type != PGPPrivateKeyPemType {
+		t.Fatalf("expected a %s pem block, got %+v", PGPPrivateKeyPemType, p)
+	}
+
+	md, err := openpgp.ReadMessage(bytes.NewReader(p.Bytes), openpgp.EntityList{entity}, nil, nil)
+	if err != nil {
+		t.Fatalf("openpgp.ReadMessage: %v", err)
+	}
+	x509Encoded, err := ioutil.ReadAll(md.UnverifiedBody)
+	if err != nil {
+		t.Fatalf("reading decrypted body: %v", err)
+	}
+
+	decoded, err := x509.ParsePKCS8PrivateKey(x509Encoded)
+	if err != nil {
+		t.Fatalf("ParsePKCS8PrivateKey: %v", err)
+	}
+	decodedPriv, ok := decoded.(ed25519.PrivateKey)
+	if !ok {
+		t.Fatalf("decoded key type = %T, want ed25519.PrivateKey", decoded)
+	}
+	if !reflect.DeepEqual(decodedPriv, priv) {
+		t.Fatal("decrypted private key does not match the one that was encrypted")
+	}
+}
+
+func TestMarshallKeyPairWithPGPRequiresRecipient(t *testing.T) {
+	priv, err := GenerateED25519PrivateKey()
+	if err != nil {
+		t.Fatalf("GenerateED25519PrivateKey: %v", err)
+	}
+
+	if _, err := MarshallKeyPairWithPGP(Key{priv, priv.Public()}, nil); err == nil {
+		t.Fatal("expected an error with no recipients")
+	}
+}
@@ -0,0 +1,234 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pkcs11key lets cosign sign with a private key that lives on a
+// PKCS#11 token (an HSM or a smart card) instead of on disk. It registers a
+// cosign.PrivateKeyProvider for the "pkcs11" URI scheme (RFC 7512); import
+// the package for its side effect to make `--key pkcs11:...` work:
+//
+//	import _ "github.com/sigstore/cosign/pkg/cosign/pkcs11key"
+package pkcs11key
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/asn1"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+	"github.com/pkg/errors"
+
+	"github.com/sigstore/cosign/pkg/cosign"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+func init() {
+	cosign.RegisterPrivateKeyProvider("pkcs11", cosign.PrivateKeyProviderFunc(loadFromURI))
+}
+
+func loadFromURI(uri string, pf cosign.PassFunc) (signature.SignerVerifier, error) {
+	attrs, err := parseURI(uri)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing pkcs11 uri")
+	}
+
+	pin, err := pf(false)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSignerVerifier(attrs, pin)
+}
+
+// SignerVerifier signs with a private key object held by a PKCS#11 token.
+// The private key material never leaves the token: Sign delegates to the
+// module's C_Sign, and the key handle is released when the process exits.
+type SignerVerifier struct {
+	mu      sync.Mutex
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+
+	privateKeyHandle pkcs11.ObjectHandle
+	hash             crypto.Hash
+	pub              crypto.PublicKey
+}
+
+func newSignerVerifier(attrs *uriAttributes, pin []byte) (*SignerVerifier, error) {
+	ctx := pkcs11.New(attrs.modulePath)
+	if ctx == nil {
+		return nil, fmt.Errorf("pkcs11: failed to load module %q", attrs.modulePath)
+	}
+	if err := ctx.Initialize(); err != nil {
+		return nil, errors.Wrap(err, "initializing pkcs11 module")
+	}
+
+	session, err := openSession(ctx, attrs)
+	if err != nil {
+		ctx.Destroy()
+		return nil, err
+	}
+
+	if err := ctx.Login(session, pkcs11.CKU_USER, string(pin)); err != nil {
+		ctx.CloseSession(session) //nolint:errcheck
+		ctx.Destroy()
+		return nil, errors.Wrap(err, "pkcs11 login")
+	}
+
+	privHandle, err := findObject(ctx, session, pkcs11.CKO_PRIVATE_KEY, attrs)
+	if err != nil {
+		ctx.CloseSession(session) //nolint:errcheck
+		ctx.Destroy()
+		return nil, err
+	}
+
+	pubHandle, err := findObject(ctx, session, pkcs11.CKO_PUBLIC_KEY, attrs)
+	if err != nil {
+		ctx.CloseSession(session) //nolint:errcheck
+		ctx.Destroy()
+		return nil, err
+	}
+
+	pub, hash, err := publicKey(ctx, session, pubHandle)
+	if err != nil {
+		ctx.CloseSession(session) //nolint:errcheck
+		ctx.Destroy()
+		return nil, err
+	}
+
+	return &SignerVerifier{
+		ctx:              ctx,
+		session:          session,
+		privateKeyHandle: privHandle,
+		hash:             hash,
+		pub:              pub,
+	}, nil
+}
+
+// Close logs the token out, closes the session and unloads the module.
+//
+// cosign.LoadPrivateKeyFromURI and cosign.PrivateKeyProvider.LoadPrivateKey
+// return the narrower signature.SignerVerifier, so callers that want to
+// release the PKCS#11 session promptly (rather than leaving it open until
+// process exit) need to type-assert for io.Closer themselves:
+//
+//	sv, err := cosign.LoadPrivateKeyFromURI(keyRef, pf)
+//	if closer, ok := sv.(io.Closer); ok {
+//		defer closer.Close()
+//	}
+func (s *SignerVerifier) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.ctx.Logout(s.session)
+	if closeErr := s.ctx.CloseSession(s.session); err == nil {
+		err = closeErr
+	}
+	s.ctx.Destroy()
+	return err
+}
+
+func (s *SignerVerifier) PublicKey(_ ...signature.PublicKeyOption) (crypto.PublicKey, error) {
+	return s.pub, nil
+}
+
+func (s *SignerVerifier) SignMessage(message io.Reader, _ ...signature.SignOption) ([]byte, error) {
+	digest, err := hashReader(s.hash, message)
+	if err != nil {
+		return nil, err
+	}
+
+	mechanism, signInput, err := signMechanismAndInput(s.pub, s.hash, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ctx.SignInit(s.session, []*pkcs11.Mechanism{mechanism}, s.privateKeyHandle); err != nil {
+		return nil, errors.Wrap(err, "pkcs11 SignInit")
+	}
+	sig, err := s.ctx.Sign(s.session, signInput)
+	if err != nil {
+		return nil, errors.Wrap(err, "pkcs11 Sign")
+	}
+	if _, ok := s.pub.(*ecdsa.PublicKey); ok {
+		// CKM_ECDSA returns raw r||s; re-encode it as the ASN.1 DER
+		// signature that VerifySignature and on-disk ECDSA keys use.
+		half := len(sig) / 2
+		return asn1.Marshal(struct{ R, S *big.Int }{
+			new(big.Int).SetBytes(sig[:half]),
+			new(big.Int).SetBytes(sig[half:]),
+		})
+	}
+	return sig, nil
+}
+
+func (s *SignerVerifier) VerifySignature(sig, message io.Reader, _ ...signature.VerifyOption) error {
+	verifier, err := signature.LoadVerifier(s.pub, s.hash)
+	if err != nil {
+		return err
+	}
+	return verifier.VerifySignature(sig, message)
+}
+
+func hashReader(hash crypto.Hash, message io.Reader) ([]byte, error) {
+	h := hash.New()
+	raw, err := ioutil.ReadAll(message)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := h.Write(raw); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// digestInfoPrefixes are the DER-encoded DigestInfo ASN.1 prefixes that
+// RSASSA-PKCS1-v1_5 (RFC 8017 s9.2) prepends to a digest before signing it.
+// CKM_RSA_PKCS expects exactly this pre-hashed, pre-padded input; cosign
+// builds it itself rather than asking the token to hash (CKM_SHA256_RSA_PKCS
+// et al. take the *message*, not an already-computed digest, and would hash
+// it a second time).
+var digestInfoPrefixes = map[crypto.Hash][]byte{
+	crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+	crypto.SHA384: {0x30, 0x41, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x02, 0x05, 0x00, 0x04, 0x30},
+	crypto.SHA512: {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40},
+}
+
+// signMechanismAndInput picks the PKCS#11 mechanism for pub's key type and
+// builds the bytes to hand C_Sign: for ECDSA that's the raw digest
+// (CKM_ECDSA signs a pre-hashed value directly), for RSA it's the digest
+// wrapped in a DigestInfo structure and signed with CKM_RSA_PKCS.
+func signMechanismAndInput(pub crypto.PublicKey, hash crypto.Hash, digest []byte) (*pkcs11.Mechanism, []byte, error) {
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		prefix, ok := digestInfoPrefixes[hash]
+		if !ok {
+			return nil, nil, fmt.Errorf("pkcs11: unsupported RSA digest algorithm %v", hash)
+		}
+		digestInfo := append(append([]byte{}, prefix...), digest...)
+		return pkcs11.NewMechanism(pkcs11.CKM_RSA_PKCS, nil), digestInfo, nil
+	case *ecdsa.PublicKey:
+		return pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil), digest, nil
+	default:
+		return nil, nil, fmt.Errorf("pkcs11: unsupported public key type %T", pub)
+	}
+}
@@ -0,0 +1,251 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkcs11key
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/pkcs11"
+
+	"github.com/sigstore/cosign/pkg/cosign"
+)
+
+// uriAttributes is a minimal, RFC 7512-flavored decomposition of a
+// "pkcs11:" key reference, e.g.
+//
+//	pkcs11:token=signing-token;object=cosign-key;slot-id=0?module-path=/usr/lib/softhsm/libsofthsm2.so
+type uriAttributes struct {
+	modulePath string
+	token      string
+	object     string
+	slotID     int
+	hasSlotID  bool
+}
+
+func parseURI(uri string) (*uriAttributes, error) {
+	if !strings.HasPrefix(uri, "pkcs11:") {
+		return nil, fmt.Errorf("not a pkcs11 uri: %s", uri)
+	}
+	body := strings.TrimPrefix(uri, "pkcs11:")
+
+	path := body
+	query := ""
+	if idx := strings.Index(body, "?"); idx >= 0 {
+		path, query = body[:idx], body[idx+1:]
+	}
+
+	attrs := &uriAttributes{}
+	for _, kv := range splitNonEmpty(path, ";") {
+		if err := attrs.set(kv); err != nil {
+			return nil, err
+		}
+	}
+	for _, kv := range splitNonEmpty(query, "&") {
+		if err := attrs.set(kv); err != nil {
+			return nil, err
+		}
+	}
+
+	if attrs.modulePath == "" {
+		return nil, fmt.Errorf("pkcs11 uri %q is missing module-path", uri)
+	}
+	return attrs, nil
+}
+
+func splitNonEmpty(s, sep string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, sep)
+}
+
+func (a *uriAttributes) set(kv string) error {
+	parts := strings.SplitN(kv, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("malformed pkcs11 uri attribute: %s", kv)
+	}
+	key, value := parts[0], parts[1]
+	switch key {
+	case "module-path":
+		a.modulePath = value
+	case "token":
+		a.token = value
+	case "object":
+		a.object = value
+	case "slot-id":
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid slot-id %q: %w", value, err)
+		}
+		a.slotID = id
+		a.hasSlotID = true
+	}
+	return nil
+}
+
+func openSession(ctx *pkcs11.Ctx, attrs *uriAttributes) (pkcs11.SessionHandle, error) {
+	slot, err := findSlot(ctx, attrs)
+	if err != nil {
+		return 0, err
+	}
+	return ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+}
+
+func findSlot(ctx *pkcs11.Ctx, attrs *uriAttributes) (uint, error) {
+	if attrs.hasSlotID {
+		return uint(attrs.slotID), nil
+	}
+
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, fmt.Errorf("pkcs11: listing slots: %w", err)
+	}
+	if attrs.token == "" {
+		if len(slots) == 0 {
+			return 0, fmt.Errorf("pkcs11: no slots with a token present")
+		}
+		return slots[0], nil
+	}
+	for _, slot := range slots {
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil {
+			continue
+		}
+		if info.Label == attrs.token {
+			return slot, nil
+		}
+	}
+	return 0, fmt.Errorf("pkcs11: no slot found for token %q", attrs.token)
+}
+
+func findObject(ctx *pkcs11.Ctx, session pkcs11.SessionHandle, class uint, attrs *uriAttributes) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+	}
+	if attrs.object != "" {
+		template = append(template, pkcs11.NewAttribute(pkcs11.CKA_LABEL, attrs.object))
+	}
+
+	if err := ctx.FindObjectsInit(session, template); err != nil {
+		return 0, fmt.Errorf("pkcs11: FindObjectsInit: %w", err)
+	}
+	defer ctx.FindObjectsFinal(session) //nolint:errcheck
+
+	handles, _, err := ctx.FindObjects(session, 1)
+	if err != nil {
+		return 0, fmt.Errorf("pkcs11: FindObjects: %w", err)
+	}
+	if len(handles) == 0 {
+		return 0, fmt.Errorf("pkcs11: no object found with label %q", attrs.object)
+	}
+	return handles[0], nil
+}
+
+// publicKey reads the EC_POINT/modulus+exponent attributes of a public key
+// object and returns both the decoded crypto.PublicKey and the digest
+// algorithm cosign should pair with it.
+func publicKey(ctx *pkcs11.Ctx, session pkcs11.SessionHandle, handle pkcs11.ObjectHandle) (crypto.PublicKey, crypto.Hash, error) {
+	attrs, err := ctx.GetAttributeValue(session, handle, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_MODULUS, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_PUBLIC_EXPONENT, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("pkcs11: reading public key attributes: %w", err)
+	}
+
+	byType := make(map[uint][]byte, len(attrs))
+	for _, a := range attrs {
+		byType[a.Type] = a.Value
+	}
+
+	if modulus, ok := byType[pkcs11.CKA_MODULUS]; ok && len(modulus) > 0 {
+		exponent := new(big.Int).SetBytes(byType[pkcs11.CKA_PUBLIC_EXPONENT])
+		pub := &rsa.PublicKey{
+			N: new(big.Int).SetBytes(modulus),
+			E: int(exponent.Int64()),
+		}
+		bits := pub.N.BitLen()
+		hash := crypto.SHA256
+		switch {
+		case bits >= 4096:
+			hash = crypto.SHA512
+		case bits >= 3072:
+			hash = crypto.SHA384
+		}
+		return pub, hash, nil
+	}
+
+	if point, ok := byType[pkcs11.CKA_EC_POINT]; ok && len(point) > 0 {
+		curve, err := curveFromECParams(byType[pkcs11.CKA_EC_PARAMS])
+		if err != nil {
+			return nil, 0, err
+		}
+		x, y := elliptic.Unmarshal(curve, decodeECPoint(point))
+		if x == nil {
+			return nil, 0, fmt.Errorf("pkcs11: malformed EC_POINT attribute")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, cosign.HashForECDSACurve(curve), nil
+	}
+
+	return nil, 0, fmt.Errorf("pkcs11: object %d is neither an RSA nor an EC public key", handle)
+}
+
+// namedCurveOIDs maps the CKA_EC_PARAMS object identifier (RFC 5480) to the
+// Go elliptic.Curve it names. PKCS#11 tokens encode CKA_EC_PARAMS as a DER
+// OBJECT IDENTIFIER, not the raw curve, so decoding it is the only reliable
+// way to tell a P-256 key apart from a P-384 or P-521 one.
+var namedCurveOIDs = map[string]elliptic.Curve{
+	"1.2.840.10045.3.1.7": elliptic.P256(),
+	"1.3.132.0.34":        elliptic.P384(),
+	"1.3.132.0.35":        elliptic.P521(),
+}
+
+func curveFromECParams(ecParams []byte) (elliptic.Curve, error) {
+	var oid asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(ecParams, &oid); err != nil {
+		return nil, fmt.Errorf("pkcs11: decoding CKA_EC_PARAMS: %w", err)
+	}
+	curve, ok := namedCurveOIDs[oid.String()]
+	if !ok {
+		return nil, fmt.Errorf("pkcs11: unsupported EC curve OID %s", oid.String())
+	}
+	return curve, nil
+}
+
+// decodeECPoint strips the DER OCTET STRING wrapper some tokens put around
+// CKA_EC_POINT, leaving the raw uncompressed point. The wrapper's length can
+// be long-form (P-521's 133-byte point needs two length bytes, not one), so
+// this parses it with encoding/asn1 rather than assuming a fixed 2-byte
+// header. Tokens that hand back an unwrapped point fail the OCTET STRING
+// parse and are returned unchanged.
+func decodeECPoint(point []byte) []byte {
+	var raw []byte
+	if rest, err := asn1.Unmarshal(point, &raw); err == nil && len(rest) == 0 {
+		return raw
+	}
+	return point
+}
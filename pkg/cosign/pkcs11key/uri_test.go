@@ -0,0 +1,139 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkcs11key
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"strconv"
+	"testing"
+)
+
+func TestParseURI(t *testing.T) {
+	attrs, err := parseURI("pkcs11:token=signing-token;object=cosign-key;slot-id=2?module-path=/usr/lib/softhsm/libsofthsm2.so")
+	if err != nil {
+		t.Fatalf("parseURI: %v", err)
+	}
+	if attrs.modulePath != "/usr/lib/softhsm/libsofthsm2.so" {
+		t.Errorf("modulePath = %q, want /usr/lib/softhsm/libsofthsm2.so", attrs.modulePath)
+	}
+	if attrs.token != "signing-token" {
+		t.Errorf("token = %q, want signing-token", attrs.token)
+	}
+	if attrs.object != "cosign-key" {
+		t.Errorf("object = %q, want cosign-key", attrs.object)
+	}
+	if !attrs.hasSlotID || attrs.slotID != 2 {
+		t.Errorf("slotID = %d (hasSlotID=%v), want 2 (true)", attrs.slotID, attrs.hasSlotID)
+	}
+}
+
+func TestParseURIRejectsNonPKCS11(t *testing.T) {
+	if _, err := parseURI("file:///tmp/cosign.key"); err == nil {
+		t.Fatal("expected an error for a non-pkcs11 uri")
+	}
+}
+
+func TestParseURIRequiresModulePath(t *testing.T) {
+	if _, err := parseURI("pkcs11:token=signing-token"); err == nil {
+		t.Fatal("expected an error for a uri missing module-path")
+	}
+}
+
+func TestCurveFromECParams(t *testing.T) {
+	for name, curve := range map[string]elliptic.Curve{
+		"1.2.840.10045.3.1.7": elliptic.P256(),
+		"1.3.132.0.34":        elliptic.P384(),
+		"1.3.132.0.35":        elliptic.P521(),
+	} {
+		oid, err := asn1.Marshal(mustOID(t, name))
+		if err != nil {
+			t.Fatalf("asn1.Marshal: %v", err)
+		}
+
+		got, err := curveFromECParams(oid)
+		if err != nil {
+			t.Fatalf("curveFromECParams(%s): %v", name, err)
+		}
+		if got != curve {
+			t.Errorf("curveFromECParams(%s) = %v, want %v", name, got.Params().Name, curve.Params().Name)
+		}
+	}
+}
+
+func TestCurveFromECParamsUnsupported(t *testing.T) {
+	oid, err := asn1.Marshal(mustOID(t, "1.2.3.4.5"))
+	if err != nil {
+		t.Fatalf("asn1.Marshal: %v", err)
+	}
+	if _, err := curveFromECParams(oid); err == nil {
+		t.Fatal("expected an error for an unsupported curve OID")
+	}
+}
+
+func TestDecodeECPoint(t *testing.T) {
+	// The OCTET STRING wrapper's length is short-form (one byte) up to 127
+	// bytes of content, and long-form (0x81 plus a length byte) above that -
+	// a P-521 uncompressed point (133 bytes: 0x04 plus two 66-byte coords)
+	// is the only one of the three curves that crosses that threshold.
+	for name, size := range map[string]int{
+		"p256 short-form wrapper": 65,
+		"p384 short-form wrapper": 97,
+		"p521 long-form wrapper":  133,
+	} {
+		t.Run(name, func(t *testing.T) {
+			raw := make([]byte, size)
+			raw[0] = 0x04
+			for i := 1; i < size; i++ {
+				raw[i] = byte(i)
+			}
+
+			wrapped, err := asn1.Marshal(raw)
+			if err != nil {
+				t.Fatalf("asn1.Marshal: %v", err)
+			}
+
+			got := decodeECPoint(wrapped)
+			if !bytes.Equal(got, raw) {
+				t.Fatalf("decodeECPoint returned %d bytes starting %#x, want %d bytes starting 0x04", len(got), got[0], len(raw))
+			}
+		})
+	}
+}
+
+func TestDecodeECPointAlreadyUnwrapped(t *testing.T) {
+	raw := append([]byte{0x04}, make([]byte, 64)...)
+
+	got := decodeECPoint(raw)
+	if !bytes.Equal(got, raw) {
+		t.Fatal("expected an already-unwrapped point to be returned unchanged")
+	}
+}
+
+func mustOID(t *testing.T, s string) asn1.ObjectIdentifier {
+	t.Helper()
+
+	var oid asn1.ObjectIdentifier
+	for _, part := range splitNonEmpty(s, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			t.Fatalf("parsing OID component %q: %v", part, err)
+		}
+		oid = append(oid, n)
+	}
+	return oid
+}
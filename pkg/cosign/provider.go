@@ -0,0 +1,121 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+// PrivateKeyProvider resolves a key reference URI to a SignerVerifier
+// without ever handing the raw private key bytes back to the caller. This
+// lets keys that live outside the local filesystem (an HSM, a TPM, a KMS)
+// be used anywhere cosign accepts a --key reference.
+//
+// Providers register themselves against a URI scheme with
+// RegisterPrivateKeyProvider, typically from their package's init().
+type PrivateKeyProvider interface {
+	LoadPrivateKey(uri string, pf PassFunc) (signature.SignerVerifier, error)
+}
+
+// PrivateKeyProviderFunc adapts a plain function to a PrivateKeyProvider.
+type PrivateKeyProviderFunc func(uri string, pf PassFunc) (signature.SignerVerifier, error)
+
+func (f PrivateKeyProviderFunc) LoadPrivateKey(uri string, pf PassFunc) (signature.SignerVerifier, error) {
+	return f(uri, pf)
+}
+
+var privateKeyProviders = map[string]PrivateKeyProvider{}
+
+// RegisterPrivateKeyProvider makes a PrivateKeyProvider available for key
+// references using the given URI scheme, e.g. "pkcs11". It panics if a
+// provider is already registered for that scheme, following the pattern
+// used by database/sql drivers.
+func RegisterPrivateKeyProvider(scheme string, provider PrivateKeyProvider) {
+	if _, ok := privateKeyProviders[scheme]; ok {
+		panic(fmt.Sprintf("cosign: PrivateKeyProvider already registered for scheme %q", scheme))
+	}
+	privateKeyProviders[scheme] = provider
+}
+
+// LoadPrivateKeyFromURI resolves keyRef to a SignerVerifier. A bare path or
+// a "file://" URI is read from disk and decrypted with LoadPrivateKey; an
+// "env://" URI reads the encrypted PEM from the named environment variable;
+// any other scheme is dispatched to the PrivateKeyProvider registered for
+// it (e.g. "pkcs11", provided by importing pkg/cosign/pkcs11key).
+//
+// Some providers hold a resource (a PKCS#11 session, say) that should be
+// released once signing is done. Since the returned SignerVerifier doesn't
+// expose that, callers that care should type-assert for io.Closer:
+//
+//	sv, err := LoadPrivateKeyFromURI(keyRef, pf)
+//	if closer, ok := sv.(io.Closer); ok {
+//		defer closer.Close()
+//	}
+func LoadPrivateKeyFromURI(keyRef string, pf PassFunc) (signature.SignerVerifier, error) {
+	u, err := url.Parse(keyRef)
+	if err != nil || u.Scheme == "" || u.Scheme == "file" {
+		return loadPrivateKeyFromFile(keyRef, pf)
+	}
+
+	if u.Scheme == "env" {
+		// "env:VAR" parses into Opaque, "env://VAR" into Host.
+		varName := u.Opaque
+		if varName == "" {
+			varName = u.Host
+		}
+		return loadPrivateKeyFromEnv(varName, pf)
+	}
+
+	provider, ok := privateKeyProviders[u.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("no PrivateKeyProvider registered for scheme %q", u.Scheme)
+	}
+	return provider.LoadPrivateKey(keyRef, pf)
+}
+
+func loadPrivateKeyFromFile(keyRef string, pf PassFunc) (signature.SignerVerifier, error) {
+	path := keyRef
+	if u, err := url.Parse(keyRef); err == nil && u.Scheme == "file" {
+		path = u.Path
+	}
+
+	keyBytes, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	password, err := pf(false)
+	if err != nil {
+		return nil, err
+	}
+	return LoadPrivateKey(keyBytes, password)
+}
+
+func loadPrivateKeyFromEnv(varName string, pf PassFunc) (signature.SignerVerifier, error) {
+	keyBytes := os.Getenv(varName)
+	if keyBytes == "" {
+		return nil, fmt.Errorf("environment variable %q is empty or unset", varName)
+	}
+	password, err := pf(false)
+	if err != nil {
+		return nil, err
+	}
+	return LoadPrivateKey([]byte(keyBytes), password)
+}
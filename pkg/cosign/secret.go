@@ -0,0 +1,85 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"runtime"
+
+	"github.com/awnumar/memguard"
+)
+
+// SecretBytes wraps sensitive byte material - a password, a decrypted
+// private key blob - in an mlock'd memguard.LockedBuffer so it never gets
+// paged out or lingers in a heap dump after Destroy is called. Callers that
+// take ownership of a SecretBytes are responsible for calling Destroy once
+// they're done with it.
+type SecretBytes struct {
+	buf *memguard.LockedBuffer
+}
+
+// NewSecretBytes copies b into a locked buffer and zeroes the input slice,
+// so no un-mlock'd copy of the secret survives the call.
+func NewSecretBytes(b []byte) *SecretBytes {
+	buf := memguard.NewBufferFromBytes(b)
+	return &SecretBytes{buf: buf}
+}
+
+// Bytes returns the underlying plaintext. The returned slice aliases the
+// locked buffer and must not be retained past a call to Destroy.
+func (s *SecretBytes) Bytes() []byte {
+	if s == nil || s.buf == nil {
+		return nil
+	}
+	return s.buf.Bytes()
+}
+
+// Destroy wipes the locked buffer. It is safe to call on a nil SecretBytes
+// and safe to call more than once.
+func (s *SecretBytes) Destroy() {
+	if s == nil || s.buf == nil {
+		return
+	}
+	s.buf.Destroy()
+}
+
+// zeroizeECDSAOnFinalize arranges for priv's scalar to be overwritten once
+// priv becomes unreachable, so a key loaded for a single sign/verify call
+// doesn't linger in memory for the rest of the process's life.
+func zeroizeECDSAOnFinalize(priv *ecdsa.PrivateKey) {
+	runtime.SetFinalizer(priv, func(p *ecdsa.PrivateKey) {
+		if p.D != nil {
+			p.D.SetInt64(0)
+		}
+	})
+}
+
+// zeroizeRSAOnFinalize is the RSA equivalent of zeroizeECDSAOnFinalize: it
+// clears the private exponent and CRT precomputed values.
+func zeroizeRSAOnFinalize(priv *rsa.PrivateKey) {
+	runtime.SetFinalizer(priv, func(p *rsa.PrivateKey) {
+		if p.D != nil {
+			p.D.SetInt64(0)
+		}
+		for _, prime := range p.Primes {
+			prime.SetInt64(0)
+		}
+		p.Precomputed.Dp.SetInt64(0)
+		p.Precomputed.Dq.SetInt64(0)
+		p.Precomputed.Qinv.SetInt64(0)
+	})
+}
@@ -0,0 +1,74 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSecretBytesRoundTrip(t *testing.T) {
+	want := []byte("super secret material")
+	// NewSecretBytes takes ownership of its input and zeroes it, so pass a copy.
+	secret := NewSecretBytes(append([]byte{}, want...))
+	defer secret.Destroy()
+
+	if !bytes.Equal(secret.Bytes(), want) {
+		t.Fatalf("Bytes() = %q, want %q", secret.Bytes(), want)
+	}
+}
+
+func TestSecretBytesZeroesInput(t *testing.T) {
+	input := []byte("super secret material")
+	_ = NewSecretBytes(input)
+
+	for _, b := range input {
+		if b != 0 {
+			t.Fatal("expected NewSecretBytes to zero its input slice")
+		}
+	}
+}
+
+func TestSecretBytesDestroyIsSafeToCallTwice(t *testing.T) {
+	secret := NewSecretBytes([]byte("super secret material"))
+	secret.Destroy()
+	secret.Destroy()
+}
+
+func TestSecretBytesNilIsSafe(t *testing.T) {
+	var secret *SecretBytes
+	if secret.Bytes() != nil {
+		t.Fatal("expected Bytes() on a nil SecretBytes to return nil")
+	}
+	secret.Destroy()
+}
+
+func TestKeysDestroyWipesPassword(t *testing.T) {
+	keys, err := GenerateKeyPairEd25519(staticPass("s3cret"))
+	if err != nil {
+		t.Fatalf("GenerateKeyPairEd25519: %v", err)
+	}
+
+	if !bytes.Equal(keys.Password(), []byte("s3cret")) {
+		t.Fatal("expected Password() to return the password before Destroy")
+	}
+
+	keys.Destroy()
+
+	if len(keys.Password()) != 0 {
+		t.Fatal("expected Destroy to wipe the password")
+	}
+}